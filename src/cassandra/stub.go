@@ -0,0 +1,19 @@
+package cassandra
+
+type KsDef struct {
+	CfDefs []*CfDef
+}
+
+type CfDef struct {
+	Name                   string
+	ColumnType             *string
+	ComparatorType         *string
+	DefaultValidationClass *string
+	KeyValidationClass     *string
+	ColumnMetadata         *[]*ColumnDef
+}
+
+type ColumnDef struct {
+	Name            []byte
+	ValidationClass string
+}
@@ -0,0 +1,139 @@
+package gossie
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// fieldOp is a pair of closures specialized to one field's cassandraType,
+// operating directly on an unsafe.Pointer to the struct instead of going
+// through reflect.Value.Interface() and a generic Marshal/Unmarshal type
+// switch on every row.
+type fieldOp struct {
+	marshal   func(p unsafe.Pointer) ([]byte, error)
+	unmarshal func(p unsafe.Pointer, b []byte) error
+}
+
+// instr is one compiled field access: offset is the field's byte offset
+// within the struct (following embedded structs the same way
+// fieldInfo.index does), op is its fast-path fieldOp, which is the zero
+// value when no fast path is compiled for this field's cassandraType.
+type instr struct {
+	field  *fieldInfo
+	offset uintptr
+	op     fieldOp
+}
+
+var errNoFastPath = errors.New("no compiled fast path for this field's cassandraType")
+
+// marshalValue runs the compiled fast path for this field, or returns
+// errNoFastPath so the caller can fall back to fieldInfo.marshalValue.
+func (in *instr) marshalValue(base unsafe.Pointer) ([]byte, error) {
+	if in.op.marshal == nil {
+		return nil, errNoFastPath
+	}
+	return in.op.marshal(unsafe.Pointer(uintptr(base) + in.offset))
+}
+
+// unmarshalValue runs the compiled fast path for this field, or returns
+// errNoFastPath so the caller can fall back to fieldInfo.unmarshalValue.
+func (in *instr) unmarshalValue(base unsafe.Pointer, b []byte) error {
+	if in.op.unmarshal == nil {
+		return errNoFastPath
+	}
+	return in.op.unmarshal(unsafe.Pointer(uintptr(base)+in.offset), b)
+}
+
+// engine is a precompiled marshal/unmarshal plan for a single Go type,
+// analogous to protobuf's table_marshal/table_unmarshal: built once per
+// reflect.Type and cached. sparseMapping.Map/Unmap walk its fields and run
+// a field's compiled fast path where there is one; they still reach each
+// field with reflect.Value.FieldByName in Map, look fields up by name in
+// maps in Unmap, and use the reflection-based fieldInfo.marshalValue/
+// unmarshalValue for the key, the composite components and every field
+// without a fast path. MarshalKey and MarshalComponent do not use it.
+type engine struct {
+	ordered []*instr
+	byName  map[string]*instr
+}
+
+var engineCache sync.Map // reflect.Type -> *engine
+
+// engineFor returns the cached engine for si's type, compiling and caching
+// it on first use.
+func engineFor(si *structInspection) *engine {
+	if cached, found := engineCache.Load(si.rtype); found {
+		return cached.(*engine)
+	}
+
+	e := &engine{byName: make(map[string]*instr, len(si.orderedFields))}
+	for _, f := range si.orderedFields {
+		in := &instr{
+			field:  f,
+			offset: fieldOffset(si.rtype, f.index),
+			op:     compileFieldOp(f),
+		}
+		e.ordered = append(e.ordered, in)
+		e.byName[f.name] = in
+	}
+
+	actual, _ := engineCache.LoadOrStore(si.rtype, e)
+	return actual.(*engine)
+}
+
+// fieldOffset sums the byte offset of index within t, following embedded
+// structs the same way fieldInfo.index/reflect.Value.FieldByIndex does.
+func fieldOffset(t reflect.Type, index []int) uintptr {
+	var offset uintptr
+	cur := t
+	for _, i := range index {
+		sf := cur.Field(i)
+		offset += sf.Offset
+		cur = sf.Type
+	}
+	return offset
+}
+
+// compileFieldOp builds the fast-path closures for the cassandraTypes
+// gossie maps most often. Anything else is left with a zero fieldOp, which
+// falls back to the generic, reflection-based fieldInfo.marshalValue/
+// unmarshalValue.
+func compileFieldOp(f *fieldInfo) fieldOp {
+	if f.cassandraType.Reversed {
+		// ReversedType needs its marshaled bytes complemented (see
+		// reverseBytes), which the fast paths below don't do; fall back to
+		// the generic, reversal-aware fieldInfo.marshalValue/unmarshalValue.
+		return fieldOp{}
+	}
+	switch f.cassandraType.Class {
+	case longClassName:
+		return fieldOp{
+			marshal: func(p unsafe.Pointer) ([]byte, error) {
+				b := make([]byte, 8)
+				binary.BigEndian.PutUint64(b, uint64(*(*int64)(p)))
+				return b, nil
+			},
+			unmarshal: func(p unsafe.Pointer, b []byte) error {
+				if len(b) != 8 {
+					return errors.New("LongType column value is not 8 bytes long")
+				}
+				*(*int64)(p) = int64(binary.BigEndian.Uint64(b))
+				return nil
+			},
+		}
+	case utf8ClassName:
+		return fieldOp{
+			marshal: func(p unsafe.Pointer) ([]byte, error) {
+				return []byte(*(*string)(p)), nil
+			},
+			unmarshal: func(p unsafe.Pointer, b []byte) error {
+				*(*string)(p) = string(b)
+				return nil
+			},
+		}
+	}
+	return fieldOp{}
+}
@@ -0,0 +1,212 @@
+package gossie
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// fieldInfo describes a single struct field mapped to a Cassandra column.
+// index is the path passed to reflect.Value.FieldByIndex to reach the
+// field; for a field declared directly on the mapped struct it is a single
+// element, for a field promoted from an embedded struct it is longer.
+type fieldInfo struct {
+	index         []int
+	name          string
+	cassandraName string
+	cassandraType TypeClass
+}
+
+// isDynamicField reports whether f is a map, slice or discriminated
+// interface field: one that is expanded into several columns at Map/Unmap
+// time (see sparseMapping.mapMultiValueField/mapDiscriminatedField) instead
+// of being marshaled through a single cassandraType, which inspectStruct
+// leaves as the zero TypeClass for exactly these fields. Callers that
+// enumerate si.orderedFields to emit or validate a single column per field
+// (schema DDL generation, Validate) must skip these.
+func isDynamicField(f *fieldInfo) bool {
+	return f.cassandraType.Class == ""
+}
+
+// marshalValue marshals f out of v using its cassandraType, complementing
+// the resulting bytes when cassandraType is wrapped in ReversedType (see
+// reverseBytes for the orderings this inverts).
+func (f *fieldInfo) marshalValue(v *reflect.Value) ([]byte, error) {
+	fv := v.FieldByIndex(f.index)
+	b, err := Marshal(fv.Interface(), f.cassandraType)
+	if err != nil {
+		return nil, errors.New(fmt.Sprint("Error marshaling field ", f.name, ":", err))
+	}
+	if f.cassandraType.Reversed {
+		b = reverseBytes(b)
+	}
+	return b, nil
+}
+
+func (f *fieldInfo) unmarshalValue(b []byte, v *reflect.Value) error {
+	if f.cassandraType.Reversed {
+		b = reverseBytes(b)
+	}
+	fv := v.FieldByIndex(f.index)
+	if err := Unmarshal(b, f.cassandraType, fv.Addr().Interface()); err != nil {
+		return errors.New(fmt.Sprint("Error unmarshaling field ", f.name, ":", err))
+	}
+	return nil
+}
+
+func (f *fieldInfo) marshalName() ([]byte, error) {
+	b, err := Marshal(f.cassandraName, UTF8Type)
+	if err != nil {
+		return nil, errors.New(fmt.Sprint("Error marshaling column name for field ", f.name, ":", err))
+	}
+	return b, nil
+}
+
+// structInspection is the result of walking a mapped struct type: the
+// global 'cf'/'key'/'cols'/'value'/'mapping' tags, plus every mapped field
+// indexed both by its Go field name and by its (possibly renamed)
+// Cassandra column name.
+type structInspection struct {
+	rtype           reflect.Type
+	globalTags      map[string]string
+	goFields        map[string]*fieldInfo
+	cassandraFields map[string]*fieldInfo
+	orderedFields   []*fieldInfo
+}
+
+var globalTagNames = map[string]bool{
+	"cf": true, "key": true, "cols": true, "value": true, "mapping": true,
+}
+
+// validateAndInspectStruct dereferences source, checks it is a struct, and
+// walks its fields building a structInspection. Fields inside anonymous
+// (embedded) structs are followed recursively, letting callers factor
+// common columns (timestamps, audit fields, tenant id) into a shared base
+// struct the way encoding/json does. A field declared directly on the
+// struct always wins over one promoted from an embedded struct with the
+// same name, and the struct's own global tags always win over the same
+// tag found on an embedded struct.
+func validateAndInspectStruct(source interface{}) (*reflect.Value, *structInspection, error) {
+	rv := reflect.ValueOf(source)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, nil, errors.New(fmt.Sprint("Passed value of type ", rv.Type(), " is not a struct or a pointer to a struct"))
+	}
+
+	if cached, found := structInspectionCache.Load(rv.Type()); found {
+		return &rv, cached.(*structInspection), nil
+	}
+
+	si, err := inspectStruct(rv.Type())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	actual, _ := structInspectionCache.LoadOrStore(rv.Type(), si)
+	return &rv, actual.(*structInspection), nil
+}
+
+// structInspectionCache memoizes inspectStruct per reflect.Type: Map/Unmap
+// run on the hot path for every row, and re-walking a struct's fields and
+// tags that many times is pure overhead once the shape of the type is
+// known.
+var structInspectionCache sync.Map // reflect.Type -> *structInspection
+
+// inspectStruct walks t's fields once, building its structInspection.
+func inspectStruct(t reflect.Type) (*structInspection, error) {
+	si := &structInspection{
+		rtype:           t,
+		globalTags:      make(map[string]string),
+		goFields:        make(map[string]*fieldInfo),
+		cassandraFields: make(map[string]*fieldInfo),
+	}
+
+	type level struct {
+		t     reflect.Type
+		index []int
+	}
+	queue := []level{{t: si.rtype}}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for i := 0; i < cur.t.NumField(); i++ {
+			sf := cur.t.Field(i)
+
+			index := make([]int, len(cur.index)+1)
+			copy(index, cur.index)
+			index[len(cur.index)] = i
+
+			for k, v := range globalTagsOf(sf.Tag) {
+				if _, found := si.globalTags[k]; !found {
+					si.globalTags[k] = v
+				}
+			}
+
+			if sf.Name == "_" {
+				continue
+			}
+
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				queue = append(queue, level{t: sf.Type, index: index})
+				continue
+			}
+
+			if sf.PkgPath != "" {
+				// unexported field, cannot be marshaled through reflection
+				continue
+			}
+
+			if _, found := si.goFields[sf.Name]; found {
+				// a shallower field with this name already won
+				continue
+			}
+
+			cassandraName := sf.Name
+			if name, found := sf.Tag.Lookup("name"); found {
+				cassandraName = name
+			}
+			if _, found := si.cassandraFields[cassandraName]; found {
+				continue
+			}
+
+			f := &fieldInfo{
+				index:         index,
+				name:          sf.Name,
+				cassandraName: cassandraName,
+			}
+			// map, slice and interface (polymorphic) fields are expanded
+			// into several columns at Map/Unmap time instead of being
+			// marshaled as a single value, so they have no cassandraType
+			// of their own
+			switch sf.Type.Kind() {
+			case reflect.Map, reflect.Slice, reflect.Interface:
+			default:
+				f.cassandraType = defaultType(sf.Type)
+			}
+
+			si.goFields[sf.Name] = f
+			si.cassandraFields[cassandraName] = f
+			si.orderedFields = append(si.orderedFields, f)
+		}
+	}
+
+	return si, nil
+}
+
+// globalTagsOf extracts the struct-level mapping tags ('cf', 'key', 'cols',
+// 'value', 'mapping') from a field's tag, if present. These are
+// conventionally declared once on a blank '_' field.
+func globalTagsOf(tag reflect.StructTag) map[string]string {
+	tags := make(map[string]string)
+	for name := range globalTagNames {
+		if v, found := tag.Lookup(name); found {
+			tags[name] = v
+		}
+	}
+	return tags
+}
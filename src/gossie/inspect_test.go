@@ -0,0 +1,66 @@
+package gossie
+
+import (
+	"reflect"
+	"testing"
+)
+
+type inspectBase struct {
+	_         struct{} `cf:"from_base" mapping:"compact"`
+	CreatedAt int64
+}
+
+type inspectOverriddenBase struct {
+	Name string
+}
+
+type inspectEmbeddedEntity struct {
+	inspectBase
+	inspectOverriddenBase
+	_    struct{} `cf:"embedded" key:"Id"`
+	Id   string
+	Name string // shadows inspectOverriddenBase.Name
+}
+
+func TestInspectStructPromotesEmbeddedFields(t *testing.T) {
+	si, err := inspectStruct(reflect.TypeOf(inspectEmbeddedEntity{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, found := si.goFields["CreatedAt"]; !found {
+		t.Fatal("CreatedAt promoted from the embedded inspectBase was not found")
+	}
+	if len(si.goFields["CreatedAt"].index) != 2 {
+		t.Fatalf("CreatedAt index path = %v, want a 2-element path through inspectBase", si.goFields["CreatedAt"].index)
+	}
+}
+
+func TestInspectStructOuterFieldWinsOverEmbedded(t *testing.T) {
+	si, err := inspectStruct(reflect.TypeOf(inspectEmbeddedEntity{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, found := si.goFields["Name"]
+	if !found {
+		t.Fatal("Name not found")
+	}
+	if len(f.index) != 1 {
+		t.Fatalf("Name index path = %v, want the 1-element path to the outer struct's own field, not the embedded one", f.index)
+	}
+}
+
+func TestInspectStructOuterGlobalTagsWinOverEmbedded(t *testing.T) {
+	si, err := inspectStruct(reflect.TypeOf(inspectEmbeddedEntity{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if si.globalTags["cf"] != "embedded" {
+		t.Fatalf("globalTags[cf] = %q, want the outer struct's own 'cf' tag to win over the embedded inspectBase's", si.globalTags["cf"])
+	}
+	if si.globalTags["key"] != "Id" {
+		t.Fatalf("globalTags[key] = %q, want Id", si.globalTags["key"])
+	}
+}
@@ -5,12 +5,11 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"unsafe"
 )
 
 /*
 	ideas:
-	mapping for Go maps
-	mapping for Go slices (N slices?)
 */
 
 // Mapping maps the type of a Go object to/from a Cassandra row.
@@ -225,6 +224,9 @@ func (m *sparseMapping) MarshalComponent(component interface{}, position int) ([
 	if err != nil {
 		return nil, errors.New(fmt.Sprint("Error marshaling passed value for a composite component in field ", f.name, ":", err))
 	}
+	if f.cassandraType.Reversed {
+		b = reverseBytes(b)
+	}
 	return b, nil
 }
 
@@ -270,14 +272,40 @@ func (m *sparseMapping) Map(source interface{}) (*Row, error) {
 		return nil, err
 	}
 
+	e := engineFor(si)
+	var base unsafe.Pointer
+	if v.CanAddr() {
+		base = unsafe.Pointer(v.UnsafeAddr())
+	}
+
 	// add columns
-	for _, f := range si.orderedFields {
+	for _, in := range e.ordered {
+		f := in.field
 		if f.name == m.key {
 			continue
 		}
 		if _, found := m.componentsMap[f.name]; found {
 			continue
 		}
+
+		fv := v.FieldByName(f.name)
+		if fv.Kind() == reflect.Map || fv.Kind() == reflect.Slice {
+			cols, err := m.mapMultiValueField(si, f, fv, composite)
+			if err != nil {
+				return nil, err
+			}
+			row.Columns = append(row.Columns, cols...)
+			continue
+		}
+		if fv.Kind() == reflect.Interface {
+			cols, err := m.mapDiscriminatedField(si, f, fv, composite)
+			if err != nil {
+				return nil, err
+			}
+			row.Columns = append(row.Columns, cols...)
+			continue
+		}
+
 		columnName, err := f.marshalName()
 		if err != nil {
 			return nil, err
@@ -295,7 +323,13 @@ func (m *sparseMapping) Map(source interface{}) (*Row, error) {
 		} else {
 			cp = append(cp, columnName...)
 		}
-		columnValue, err := f.marshalValue(v)
+		var columnValue []byte
+		if base != nil {
+			columnValue, err = in.marshalValue(base)
+		}
+		if base == nil || err == errNoFastPath {
+			columnValue, err = f.marshalValue(v)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -305,6 +339,130 @@ func (m *sparseMapping) Map(source interface{}) (*Row, error) {
 	return row, nil
 }
 
+// mapMultiValueField expands a Go map or slice field into one Cassandra
+// column per entry, with a composite column name of
+// (components..., fieldName, mapKey_or_sliceIndex). The sub-key is
+// marshaled using the type named in the field's 'subkey' struct tag.
+func (m *sparseMapping) mapMultiValueField(si *structInspection, f *fieldInfo, fv reflect.Value, composite []byte) ([]*Column, error) {
+	subkeyType, err := subkeyTypeClass(si, f)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldName, err := f.marshalName()
+	if err != nil {
+		return nil, err
+	}
+	elemType := defaultType(fv.Type().Elem())
+
+	var cols []*Column
+	appendColumn := func(subkey, elem interface{}) error {
+		subkeyBytes, err := Marshal(subkey, subkeyType)
+		if err != nil {
+			return errors.New(fmt.Sprint("Error marshaling sub-key for field ", f.name, ":", err))
+		}
+		elemBytes, err := Marshal(elem, elemType)
+		if err != nil {
+			return errors.New(fmt.Sprint("Error marshaling value for field ", f.name, ":", err))
+		}
+		cp := make([]byte, 0, len(composite)+len(fieldName)+len(subkeyBytes)+6)
+		cp = append(cp, composite...)
+		cp = append(cp, packComposite(fieldName, eocEquals)...)
+		cp = append(cp, packComposite(subkeyBytes, eocEquals)...)
+		cols = append(cols, &Column{Name: cp, Value: elemBytes})
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.Map:
+		for _, k := range fv.MapKeys() {
+			if err := appendColumn(k.Interface(), fv.MapIndex(k).Interface()); err != nil {
+				return nil, err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < fv.Len(); i++ {
+			if err := appendColumn(i, fv.Index(i).Interface()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return cols, nil
+}
+
+// maxSliceIndex bounds the slice index decoded off a column's subkey: the
+// value comes from live Cassandra data during Unmap, not something the
+// caller controls, so a negative or absurdly large decoded index (corrupt
+// data, a wrong 'subkey' tag, a byte-order mismatch) must be rejected
+// instead of panicking on reflect.Value.Index or growing the slice without
+// bound.
+const maxSliceIndex = 1 << 20
+
+// unmapMultiValueColumn decodes a single column belonging to a map or slice
+// field, lazily allocating the destination map/slice and setting the
+// decoded sub-key/value pair into it.
+func (m *sparseMapping) unmapMultiValueColumn(si *structInspection, v *reflect.Value, f *fieldInfo, subkey, value []byte) error {
+	subkeyType, err := subkeyTypeClass(si, f)
+	if err != nil {
+		return err
+	}
+
+	fv := v.FieldByName(f.name)
+	elemType := defaultType(fv.Type().Elem())
+
+	switch fv.Kind() {
+	case reflect.Map:
+		if fv.IsNil() {
+			fv.Set(reflect.MakeMap(fv.Type()))
+		}
+		keyPtr := reflect.New(fv.Type().Key())
+		if err := Unmarshal(subkey, subkeyType, keyPtr.Interface()); err != nil {
+			return errors.New(fmt.Sprint("Error unmarshaling map key for field ", f.name, ":", err))
+		}
+		valuePtr := reflect.New(fv.Type().Elem())
+		if err := Unmarshal(value, elemType, valuePtr.Interface()); err != nil {
+			return errors.New(fmt.Sprint("Error unmarshaling map value for field ", f.name, ":", err))
+		}
+		fv.SetMapIndex(reflect.Indirect(keyPtr), reflect.Indirect(valuePtr))
+	case reflect.Slice:
+		var index int
+		if err := Unmarshal(subkey, subkeyType, &index); err != nil {
+			return errors.New(fmt.Sprint("Error unmarshaling slice index for field ", f.name, ":", err))
+		}
+		if index < 0 || index > maxSliceIndex {
+			return errors.New(fmt.Sprint("Decoded slice index ", index, " out of bounds for field ", f.name))
+		}
+		for fv.Len() <= index {
+			fv.Set(reflect.Append(fv, reflect.Zero(fv.Type().Elem())))
+		}
+		valuePtr := reflect.New(fv.Type().Elem())
+		if err := Unmarshal(value, elemType, valuePtr.Interface()); err != nil {
+			return errors.New(fmt.Sprint("Error unmarshaling slice value for field ", f.name, ":", err))
+		}
+		fv.Index(index).Set(reflect.Indirect(valuePtr))
+	default:
+		return errors.New(fmt.Sprint("Field ", f.name, " is not a map or slice"))
+	}
+
+	return nil
+}
+
+// subkeyTypeClass looks up the 'subkey' struct tag for a map/slice field,
+// which names the Cassandra validator used to marshal the map key or slice
+// index that is appended to the composite column name.
+func subkeyTypeClass(si *structInspection, f *fieldInfo) (TypeClass, error) {
+	sf, found := si.rtype.FieldByName(f.name)
+	if !found {
+		return TypeClass{}, errors.New(fmt.Sprint("Field ", f.name, " not found in struct of type ", si.rtype.Name()))
+	}
+	tag, found := sf.Tag.Lookup("subkey")
+	if !found {
+		return TypeClass{}, errors.New(fmt.Sprint("Mandatory struct tag 'subkey' not found for map/slice field ", f.name))
+	}
+	return parseTypeClass(tag), nil
+}
+
 func (m *sparseMapping) startUnmap(destination interface{}, provider RowProvider) (*reflect.Value, *structInspection, error) {
 	v, si, err := validateAndInspectStruct(destination)
 	if err != nil {
@@ -356,12 +514,33 @@ func (m *sparseMapping) extractComponents(column *Column, v *reflect.Value, bias
 	return components, nil
 }
 
+// extractSparseComponents is like extractComponents but also accepts the
+// extra trailing sub-key component produced by a map/slice field, returning
+// the decoded components together with how many trailing elements (1 for a
+// regular column, 2 for a map/slice entry) are not part of the mapping's
+// own composite components.
+func (m *sparseMapping) extractSparseComponents(column *Column, v *reflect.Value) ([][]byte, int, error) {
+	var components [][]byte
+	if len(m.components) > 0 {
+		components = unpackComposite(column.Name)
+	} else {
+		components = [][]byte{column.Name}
+	}
+	bias := len(components) - len(m.components)
+	if bias != 1 && bias != 2 {
+		return components, bias, errors.New(fmt.Sprint("Returned number of components in composite column name does not match struct mapping in struct ", v.Type().Name()))
+	}
+	return components, bias, nil
+}
+
 // TODO: speed this up
 func (m *sparseMapping) isNewComponents(prev, next [][]byte, bias int) bool {
-	if len(prev) != len(next) {
-		return true
-	}
-	for i := 0; i < len(prev)-bias; i++ {
+	// prev and next only share their first len(m.components) elements: a
+	// plain column has one trailing element (the field name) and a
+	// map/slice column has two (the field name and the sub-key), so their
+	// total lengths legitimately differ from one column to the next for
+	// the same composite-key entity and cannot be compared directly.
+	for i := 0; i < len(m.components); i++ {
 		p := prev[i]
 		n := next[i]
 		if len(p) != len(n) {
@@ -382,6 +561,11 @@ func (m *sparseMapping) Unmap(destination interface{}, provider RowProvider) err
 		return err
 	}
 
+	e := engineFor(si)
+	base := unsafe.Pointer(v.UnsafeAddr())
+	discFields := discriminatorFieldsOf(si)
+	discStates := make(map[string]*discrimState)
+
 	compositeFieldsAreSet := false
 	var previousComponents [][]byte
 
@@ -401,7 +585,7 @@ func (m *sparseMapping) Unmap(destination interface{}, provider RowProvider) err
 			return err
 		}
 
-		components, err := m.extractComponents(column, v, 1)
+		components, bias, err := m.extractSparseComponents(column, v)
 		if err != nil {
 			return err
 		}
@@ -412,22 +596,42 @@ func (m *sparseMapping) Unmap(destination interface{}, provider RowProvider) err
 			}
 			compositeFieldsAreSet = true
 		} else {
-			if m.isNewComponents(previousComponents, components, 1) {
+			if m.isNewComponents(previousComponents, components, bias) {
 				provider.Rewind()
 				break
 			}
 		}
 
-		// lookup field by name
+		// lookup field by name: for a regular column this is the last
+		// component, for a map/slice entry it is the second-to-last one,
+		// followed by the sub-key
 		var name string
-		err = Unmarshal(components[len(components)-1], UTF8Type, &name)
+		err = Unmarshal(components[len(m.components)], UTF8Type, &name)
 		if err != nil {
 			return errors.New(fmt.Sprint("Error unmarshaling composite field as UTF8Type for field name in struct ", v.Type().Name(), ", error: ", err))
 		}
 		if f, found := si.cassandraFields[name]; found {
-			err := f.unmarshalValue(column.Value, v)
-			if err != nil {
-				return errors.New(fmt.Sprint("Error unmarshaling column: ", name, " value: ", err))
+			if bias == 2 {
+				if err := m.unmapMultiValueColumn(si, v, f, components[len(components)-1], column.Value); err != nil {
+					return err
+				}
+			} else {
+				in := e.byName[f.name]
+				err := in.unmarshalValue(base, column.Value)
+				if err == errNoFastPath {
+					err = f.unmarshalValue(column.Value, v)
+				}
+				if err != nil {
+					return errors.New(fmt.Sprint("Error unmarshaling column: ", name, " value: ", err))
+				}
+			}
+		} else if df, found := discFields[name]; found && bias == 1 {
+			if err := m.unmapDiscriminatorSentinel(v, df, name, column.Value, discStates); err != nil {
+				return err
+			}
+		} else if state, found := discStates[name]; found && bias == 2 {
+			if err := unmapDiscriminatorColumn(state, components[len(components)-1], column.Value); err != nil {
+				return err
 			}
 		}
 
@@ -0,0 +1,29 @@
+package gossie
+
+import "testing"
+
+// TestIsNewComponentsMixedBias reproduces the scenario this request exists
+// for: a single entity that has both a plain column (bias 1, composite
+// name (components..., fieldName)) and a map/slice column (bias 2,
+// composite name (components..., fieldName, subkey)). isNewComponents must
+// only compare the shared components prefix, not the raw slice lengths,
+// or it spuriously reports a new entity on every bias change.
+func TestIsNewComponentsMixedBias(t *testing.T) {
+	m := &sparseMapping{components: []string{"id"}}
+
+	id := []byte("same-id")
+	plainColumn := [][]byte{id, []byte("Name")}
+	mapColumn := [][]byte{id, []byte("History"), []byte("subkey")}
+
+	if m.isNewComponents(plainColumn, mapColumn, 2) {
+		t.Fatal("isNewComponents reported a new entity across columns of the same entity with different bias")
+	}
+	if m.isNewComponents(mapColumn, plainColumn, 1) {
+		t.Fatal("isNewComponents reported a new entity across columns of the same entity with different bias")
+	}
+
+	otherID := [][]byte{[]byte("different-id"), []byte("Name")}
+	if !m.isNewComponents(plainColumn, otherID, 1) {
+		t.Fatal("isNewComponents did not detect a genuinely new entity")
+	}
+}
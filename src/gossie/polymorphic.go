@@ -0,0 +1,220 @@
+package gossie
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// RegisterVariant associates a discriminator tag value with a concrete,
+// gossie-tagged prototype for a column family, so a polymorphic field
+// tagged 'discriminator' on a Mapping for that column family knows which
+// concrete type to allocate when it sees that tag value in a row. This
+// lets a single wide row hold heterogeneous entity kinds (activity feeds,
+// event logs) without a mapping per concrete type.
+func RegisterVariant(mapping Mapping, tag string, prototype interface{}) error {
+	_, si, err := validateAndInspectStruct(prototype)
+	if err != nil {
+		return err
+	}
+	if _, found := si.globalTags["key"]; !found {
+		return errors.New(fmt.Sprint("Variant prototype of type ", si.rtype.Name(), " has no mapped key field"))
+	}
+
+	variantsByTag.Store(variantKey{cf: mapping.Cf(), tag: tag}, si.rtype)
+	variantsByType.Store(variantTypeKey{cf: mapping.Cf(), rtype: si.rtype}, tag)
+	return nil
+}
+
+type variantKey struct {
+	cf  string
+	tag string
+}
+
+type variantTypeKey struct {
+	cf    string
+	rtype reflect.Type
+}
+
+var (
+	variantsByTag  sync.Map // variantKey -> reflect.Type
+	variantsByType sync.Map // variantTypeKey -> tag string
+)
+
+func lookupVariantType(cf, tag string) (reflect.Type, bool) {
+	v, found := variantsByTag.Load(variantKey{cf: cf, tag: tag})
+	if !found {
+		return nil, false
+	}
+	return v.(reflect.Type), true
+}
+
+func variantTag(cf string, rtype reflect.Type) (string, bool) {
+	v, found := variantsByType.Load(variantTypeKey{cf: cf, rtype: rtype})
+	if !found {
+		return "", false
+	}
+	return v.(string), true
+}
+
+// discriminatorFieldsOf finds the interface fields of si tagged
+// 'discriminator', keyed by the Cassandra column name named in the tag
+// (the "sentinel" column that holds the variant's tag value).
+func discriminatorFieldsOf(si *structInspection) map[string]*fieldInfo {
+	fields := make(map[string]*fieldInfo)
+	for _, f := range si.orderedFields {
+		sf, found := si.rtype.FieldByName(f.name)
+		if !found || sf.Type.Kind() != reflect.Interface {
+			continue
+		}
+		if tag, found := sf.Tag.Lookup("discriminator"); found {
+			fields[tag] = f
+		}
+	}
+	return fields
+}
+
+// discrimState tracks, for the duration of a single Unmap call, the
+// variant that was allocated for a discriminated field once its sentinel
+// column was seen.
+type discrimState struct {
+	field    *fieldInfo
+	ptr      reflect.Value // *T, already assigned into the interface field
+	nestedSi *structInspection
+}
+
+// mapDiscriminatedField encodes a discriminated interface field as a
+// sentinel column carrying the variant's registered tag, a synthetic
+// column carrying the variant's own key, and one column per remaining
+// scalar field of the variant's mapping, each named
+// (components..., sentinelName, columnName).
+func (m *sparseMapping) mapDiscriminatedField(si *structInspection, f *fieldInfo, fv reflect.Value, composite []byte) ([]*Column, error) {
+	sf, found := si.rtype.FieldByName(f.name)
+	if !found {
+		return nil, errors.New(fmt.Sprint("Field ", f.name, " not found in struct of type ", si.rtype.Name()))
+	}
+	sentinelName, found := sf.Tag.Lookup("discriminator")
+	if !found {
+		return nil, errors.New(fmt.Sprint("Mandatory struct tag 'discriminator' not found for interface field ", f.name))
+	}
+	if fv.IsNil() {
+		return nil, nil
+	}
+
+	ptr := fv.Elem()
+	if ptr.Kind() != reflect.Ptr || ptr.Elem().Kind() != reflect.Struct {
+		return nil, errors.New(fmt.Sprint("Discriminated field ", f.name, " must hold a pointer to a struct"))
+	}
+
+	tag, found := variantTag(m.cf, ptr.Elem().Type())
+	if !found {
+		return nil, errors.New(fmt.Sprint("No variant registered in column family ", m.cf, " for type ", ptr.Elem().Type()))
+	}
+
+	_, nestedSi, err := validateAndInspectStruct(ptr.Interface())
+	if err != nil {
+		return nil, err
+	}
+	nestedValue := ptr.Elem()
+	nestedKey := nestedSi.goFields[nestedSi.globalTags["key"]]
+	keyBytes, err := nestedKey.marshalValue(&nestedValue)
+	if err != nil {
+		return nil, err
+	}
+
+	sentinelBytes, err := Marshal(sentinelName, UTF8Type)
+	if err != nil {
+		return nil, err
+	}
+	prefix := make([]byte, 0, len(composite)+len(sentinelBytes)+3)
+	prefix = append(prefix, composite...)
+	prefix = append(prefix, packComposite(sentinelBytes, eocEquals)...)
+
+	addColumn := func(cols []*Column, columnName string, value []byte) ([]*Column, error) {
+		nameBytes, err := Marshal(columnName, UTF8Type)
+		if err != nil {
+			return nil, err
+		}
+		cp := make([]byte, 0, len(prefix)+len(nameBytes)+3)
+		cp = append(cp, prefix...)
+		cp = append(cp, packComposite(nameBytes, eocEquals)...)
+		return append(cols, &Column{Name: cp, Value: value}), nil
+	}
+
+	sentinelCp := make([]byte, 0, len(composite)+len(sentinelBytes)+3)
+	sentinelCp = append(sentinelCp, composite...)
+	sentinelCp = append(sentinelCp, packComposite(sentinelBytes, eocEquals)...)
+
+	cols := []*Column{{Name: sentinelCp, Value: []byte(tag)}}
+	if cols, err = addColumn(cols, "", keyBytes); err != nil {
+		return nil, err
+	}
+	for _, nf := range nestedSi.orderedFields {
+		if nf.name == nestedSi.globalTags["key"] {
+			continue
+		}
+		value, err := nf.marshalValue(&nestedValue)
+		if err != nil {
+			return nil, err
+		}
+		if cols, err = addColumn(cols, nf.cassandraName, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return cols, nil
+}
+
+// unmapDiscriminatorSentinel decodes a sentinel column, allocates the
+// registered variant it names and assigns it into the interface field,
+// remembering its state so the following nested columns can fill it in.
+func (m *sparseMapping) unmapDiscriminatorSentinel(v *reflect.Value, f *fieldInfo, sentinelName string, value []byte, states map[string]*discrimState) error {
+	var tag string
+	if err := Unmarshal(value, UTF8Type, &tag); err != nil {
+		return errors.New(fmt.Sprint("Error unmarshaling discriminator tag for field ", f.name, ":", err))
+	}
+
+	rtype, found := lookupVariantType(m.cf, tag)
+	if !found {
+		return errors.New(fmt.Sprint("No variant registered in column family ", m.cf, " for discriminator tag ", tag))
+	}
+
+	ptr := reflect.New(rtype)
+	_, nestedSi, err := validateAndInspectStruct(ptr.Interface())
+	if err != nil {
+		return err
+	}
+
+	v.FieldByName(f.name).Set(ptr)
+	states[sentinelName] = &discrimState{field: f, ptr: ptr, nestedSi: nestedSi}
+	return nil
+}
+
+// unmapDiscriminatorColumn decodes a single column belonging to an already
+// allocated variant: an empty column name carries the variant's own key,
+// anything else is looked up by name in the variant's mapping.
+func unmapDiscriminatorColumn(state *discrimState, columnName []byte, value []byte) error {
+	var name string
+	if err := Unmarshal(columnName, UTF8Type, &name); err != nil {
+		return errors.New(fmt.Sprint("Error unmarshaling variant column name for field ", state.field.name, ":", err))
+	}
+
+	nestedValue := state.ptr.Elem()
+
+	if name == "" {
+		keyField := state.nestedSi.goFields[state.nestedSi.globalTags["key"]]
+		if err := keyField.unmarshalValue(value, &nestedValue); err != nil {
+			return errors.New(fmt.Sprint("Error unmarshaling variant key for field ", state.field.name, ":", err))
+		}
+		return nil
+	}
+
+	if nf, found := state.nestedSi.cassandraFields[name]; found {
+		if err := nf.unmarshalValue(value, &nestedValue); err != nil {
+			return errors.New(fmt.Sprint("Error unmarshaling variant column ", name, " for field ", state.field.name, ":", err))
+		}
+	}
+
+	return nil
+}
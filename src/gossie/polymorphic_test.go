@@ -0,0 +1,88 @@
+package gossie
+
+import "testing"
+
+type discEmailVariant struct {
+	_       struct{} `cf:"disc_feed" key:"Id"`
+	Id      string
+	Subject string
+}
+
+type discLikeVariant struct {
+	_     struct{} `cf:"disc_feed" key:"Id"`
+	Id    string
+	Liker string
+}
+
+type discFeedEntity struct {
+	_     struct{} `cf:"disc_feed" key:"Id"`
+	Id    string
+	Event interface{} `discriminator:"kind"`
+}
+
+// TestDiscriminatorRoundTrip registers two variants on the same mapping and
+// checks that encoding one with mapDiscriminatedField and decoding the
+// resulting columns back with unmapDiscriminatorSentinel/
+// unmapDiscriminatorColumn recovers the same concrete type and field
+// values, the way sparseMapping.Map/Unmap do for a real row.
+func TestDiscriminatorRoundTrip(t *testing.T) {
+	mapping, err := NewMapping(&discFeedEntity{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm := mapping.(*sparseMapping)
+
+	if err := RegisterVariant(mapping, "email", &discEmailVariant{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterVariant(mapping, "like", &discLikeVariant{}); err != nil {
+		t.Fatal(err)
+	}
+
+	source := &discFeedEntity{
+		Id:    "entity-1",
+		Event: &discEmailVariant{Id: "email-1", Subject: "hello"},
+	}
+	v, si, err := validateAndInspectStruct(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := si.goFields["Event"]
+	fv := v.FieldByName("Event")
+
+	cols, err := sm.mapDiscriminatedField(si, f, fv, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cols) != 3 {
+		t.Fatalf("mapDiscriminatedField() returned %d columns, want 3 (sentinel, key, Subject)", len(cols))
+	}
+
+	dest := &discFeedEntity{}
+	destV, destSi, err := validateAndInspectStruct(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	destF := destSi.goFields["Event"]
+
+	states := make(map[string]*discrimState)
+	if err := sm.unmapDiscriminatorSentinel(destV, destF, "kind", cols[0].Value, states); err != nil {
+		t.Fatal(err)
+	}
+	state := states["kind"]
+	for _, c := range cols[1:] {
+		components := unpackComposite(c.Name)
+		columnName := components[len(components)-1]
+		if err := unmapDiscriminatorColumn(state, columnName, c.Value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, ok := dest.Event.(*discEmailVariant)
+	if !ok {
+		t.Fatalf("dest.Event = %T, want *discEmailVariant", dest.Event)
+	}
+	if got.Id != "email-1" || got.Subject != "hello" {
+		t.Fatalf("dest.Event = %+v, want {Id:email-1 Subject:hello}", got)
+	}
+}
@@ -1,15 +1,16 @@
 package gossie
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/HailoOSS/gossie/src/cassandra"
 )
 
 /*
 to do:
-    generate CQL schema from tagged Go structs
-    validate tagged Go structs against schemas
-    handle ReversedType
-    handle type options
 	handle composited column names in the schema (is this in use/allowed?)
 */
 
@@ -61,3 +62,132 @@ func newSchema(ksDef *cassandra.KsDef) *Schema {
 
 	return schema
 }
+
+// FromStructs generates the CQL3 "CREATE TABLE" DDL for every passed,
+// gossie-tagged struct value, so the schema used to migrate a keyspace can
+// be derived from the same tags that drive Map/Unmap instead of being
+// hand-written and left to drift.
+func (s *Schema) FromStructs(sources ...interface{}) (string, error) {
+	var buf bytes.Buffer
+	for i, source := range sources {
+		ddl, err := createTableDDL(source)
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			buf.WriteString("\n\n")
+		}
+		buf.WriteString(ddl)
+	}
+	return buf.String(), nil
+}
+
+// createTableDDL builds the CREATE TABLE statement for a single gossie-tagged
+// struct, reusing the same 'cf'/'key'/'cols'/'value'/'mapping' tags that
+// NewMapping uses to build a Mapping.
+func createTableDDL(source interface{}) (string, error) {
+	_, si, err := validateAndInspectStruct(source)
+	if err != nil {
+		return "", err
+	}
+
+	cf, found := si.globalTags["cf"]
+	if !found {
+		return "", errors.New(fmt.Sprint("Mandatory struct tag 'cf' not found in passed struct of type ", si.rtype.Name()))
+	}
+
+	key, found := si.globalTags["key"]
+	if !found {
+		return "", errors.New(fmt.Sprint("Mandatory struct tag 'key' not found in passed struct of type ", si.rtype.Name()))
+	}
+	keyField, found := si.goFields[key]
+	if !found {
+		return "", errors.New(fmt.Sprint("Key field ", key, " not found in passed struct of type ", si.rtype.Name()))
+	}
+
+	colsS := []string{}
+	if cols, found := si.globalTags["cols"]; found {
+		colsS = strings.Split(cols, ",")
+	}
+	componentFields := make([]*fieldInfo, 0, len(colsS))
+	for _, c := range colsS {
+		f, found := si.goFields[c]
+		if !found {
+			return "", errors.New(fmt.Sprint("Composite field ", c, " not found in passed struct of type ", si.rtype.Name()))
+		}
+		componentFields = append(componentFields, f)
+	}
+
+	value, hasValue := si.globalTags["value"]
+
+	mapping, found := si.globalTags["mapping"]
+	if !found {
+		mapping = "sparse"
+	}
+	compact := mapping == "compact"
+
+	excluded := map[string]bool{key: true}
+	for _, c := range colsS {
+		excluded[c] = true
+	}
+	if compact && hasValue {
+		excluded[value] = true
+	}
+
+	var columns bytes.Buffer
+	fmt.Fprintf(&columns, "    %s %s", keyField.cassandraName, cqlType(keyField.cassandraType))
+	for _, f := range componentFields {
+		fmt.Fprintf(&columns, ",\n    %s %s", f.cassandraName, cqlType(f.cassandraType))
+	}
+
+	if compact {
+		if hasValue {
+			f, found := si.goFields[value]
+			if !found {
+				return "", errors.New(fmt.Sprint("Value field ", value, " not found in passed struct of type ", si.rtype.Name()))
+			}
+			fmt.Fprintf(&columns, ",\n    %s %s", f.cassandraName, cqlType(f.cassandraType))
+		}
+	} else {
+		for _, f := range si.orderedFields {
+			if excluded[f.name] || isDynamicField(f) {
+				continue
+			}
+			fmt.Fprintf(&columns, ",\n    %s %s", f.cassandraName, cqlType(f.cassandraType))
+		}
+	}
+
+	primaryKey := "(" + keyField.cassandraName + ")"
+	for _, f := range componentFields {
+		primaryKey += ", " + f.cassandraName
+	}
+
+	ddl := fmt.Sprintf("CREATE TABLE %s (\n%s,\n    PRIMARY KEY (%s)\n)", cf, columns.String(), primaryKey)
+	if compact {
+		ddl += "\n    WITH COMPACT STORAGE"
+	}
+	ddl += ";"
+
+	return ddl, nil
+}
+
+// cqlType renders a TypeClass as a CQL3 column type, using the fully
+// qualified Cassandra marshal class as a quoted custom type so the exact
+// comparator/validator (including CompositeType(...) and friends) survives
+// the round trip.
+func cqlType(tc TypeClass) string {
+	return "'" + tc.String() + "'"
+}
+
+// CreateColumnFamily generates the CQL3 DDL for the passed, gossie-tagged
+// struct and runs it against the connected keyspace, creating the matching
+// column family so it doesn't have to be hand-maintained in a separate
+// schema file.
+func (c *Connection) CreateColumnFamily(source interface{}) error {
+	ddl, err := createTableDDL(source)
+	if err != nil {
+		return err
+	}
+	_, err = c.Cql(ddl).Execute()
+	return err
+}
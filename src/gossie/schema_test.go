@@ -0,0 +1,73 @@
+package gossie
+
+import "testing"
+
+type ddlSparseEntity struct {
+	_      struct{} `cf:"ddl_sparse" key:"Id" cols:"Bucket"`
+	Id     string
+	Bucket int64
+	Name   string `name:"display_name"`
+}
+
+type ddlCompactEntity struct {
+	_     struct{} `cf:"ddl_compact" key:"Id" value:"Count" mapping:"compact"`
+	Id    string
+	Count int64
+}
+
+func TestCreateTableDDLSparse(t *testing.T) {
+	ddl, err := createTableDDL(&ddlSparseEntity{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "CREATE TABLE ddl_sparse (\n" +
+		"    Id 'org.apache.cassandra.db.marshal.UTF8Type',\n" +
+		"    Bucket 'org.apache.cassandra.db.marshal.LongType',\n" +
+		"    display_name 'org.apache.cassandra.db.marshal.UTF8Type',\n" +
+		"    PRIMARY KEY ((Id), Bucket)\n" +
+		");"
+
+	if ddl != want {
+		t.Errorf("createTableDDL() =\n%s\nwant\n%s", ddl, want)
+	}
+}
+
+func TestCreateTableDDLCompact(t *testing.T) {
+	ddl, err := createTableDDL(&ddlCompactEntity{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "CREATE TABLE ddl_compact (\n" +
+		"    Id 'org.apache.cassandra.db.marshal.UTF8Type',\n" +
+		"    Count 'org.apache.cassandra.db.marshal.LongType',\n" +
+		"    PRIMARY KEY ((Id))\n" +
+		")\n" +
+		"    WITH COMPACT STORAGE;"
+
+	if ddl != want {
+		t.Errorf("createTableDDL() =\n%s\nwant\n%s", ddl, want)
+	}
+}
+
+func TestCreateTableDDLRenamedPrimaryKeyField(t *testing.T) {
+	type renamedKeyEntity struct {
+		_  struct{} `cf:"ddl_renamed_key" key:"Id"`
+		Id string `name:"entity_id"`
+	}
+
+	ddl, err := createTableDDL(&renamedKeyEntity{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "CREATE TABLE ddl_renamed_key (\n" +
+		"    entity_id 'org.apache.cassandra.db.marshal.UTF8Type',\n" +
+		"    PRIMARY KEY ((entity_id))\n" +
+		");"
+
+	if ddl != want {
+		t.Errorf("createTableDDL() =\n%s\nwant\n%s", ddl, want)
+	}
+}
@@ -0,0 +1,186 @@
+package gossie
+
+import (
+	"sort"
+	"strings"
+)
+
+// fully qualified Cassandra comparator/validator class names
+const (
+	bytesClassName     = "org.apache.cassandra.db.marshal.BytesType"
+	utf8ClassName      = "org.apache.cassandra.db.marshal.UTF8Type"
+	longClassName      = "org.apache.cassandra.db.marshal.LongType"
+	compositeClassName = "org.apache.cassandra.db.marshal.CompositeType"
+	reversedClassName  = "org.apache.cassandra.db.marshal.ReversedType"
+)
+
+// TypeClass describes a Cassandra comparator or validator, as used in a
+// CfDef's comparator_type/*_validation_class fields: a plain class
+// (UTF8Type, LongType, ...), optionally wrapped in ReversedType(...) for a
+// DESC-ordered clustering column, optionally parenthesized with CQL
+// Type(k=v,...) options, or a CompositeType(...) of further TypeClasses.
+type TypeClass struct {
+	Class      string
+	Composites []TypeClass
+	Reversed   bool
+	Options    map[string]string
+}
+
+var (
+	BytesType = TypeClass{Class: bytesClassName}
+	UTF8Type  = TypeClass{Class: utf8ClassName}
+	LongType  = TypeClass{Class: longClassName}
+)
+
+// parseTypeClass parses a fully qualified Cassandra comparator/validator
+// class string, as returned over Thrift in a CfDef, into a TypeClass. It
+// recursively strips a ReversedType(...) wrapper (setting Reversed), parses
+// a parenthesized Type(k=v,...) option list (setting Options), and expands
+// CompositeType(a,b,c) into Composites.
+func parseTypeClass(raw string) TypeClass {
+	raw = strings.TrimSpace(raw)
+
+	if inner, ok := unwrap(raw, reversedClassName); ok {
+		tc := parseTypeClass(inner)
+		tc.Reversed = true
+		return tc
+	}
+
+	class, args, hasArgs := splitClassArgs(raw)
+	if !hasArgs {
+		return TypeClass{Class: class}
+	}
+
+	if class == compositeClassName {
+		parts := splitTopLevel(args)
+		composites := make([]TypeClass, 0, len(parts))
+		for _, p := range parts {
+			composites = append(composites, parseTypeClass(p))
+		}
+		return TypeClass{Class: class, Composites: composites}
+	}
+
+	// a Type(k=v,...) option list rather than a composite of sub-types
+	options := make(map[string]string)
+	for _, p := range splitTopLevel(args) {
+		if eq := strings.IndexByte(p, '='); eq >= 0 {
+			options[strings.TrimSpace(p[:eq])] = strings.TrimSpace(p[eq+1:])
+		}
+	}
+	return TypeClass{Class: class, Options: options}
+}
+
+// unwrap strips "prefix(...)" from raw and returns the inner string, if
+// raw is indeed wrapped in prefix.
+func unwrap(raw, prefix string) (string, bool) {
+	if !strings.HasPrefix(raw, prefix+"(") || !strings.HasSuffix(raw, ")") {
+		return "", false
+	}
+	return raw[len(prefix)+1 : len(raw)-1], true
+}
+
+// splitClassArgs splits "Class(args)" into "Class" and "args"; a raw
+// string with no parenthesized argument list returns hasArgs = false.
+func splitClassArgs(raw string) (class string, args string, hasArgs bool) {
+	open := strings.IndexByte(raw, '(')
+	if open < 0 || !strings.HasSuffix(raw, ")") {
+		return raw, "", false
+	}
+	return raw[:open], raw[open+1 : len(raw)-1], true
+}
+
+// splitTopLevel splits a comma separated list, ignoring commas nested
+// inside a further parenthesized argument list.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth := 0
+	last := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[last:i]))
+				last = i + 1
+			}
+		}
+	}
+	if last < len(s) {
+		parts = append(parts, strings.TrimSpace(s[last:]))
+	}
+	return parts
+}
+
+// String renders a TypeClass back into the fully qualified class string
+// Cassandra expects, the inverse of parseTypeClass.
+func (tc TypeClass) String() string {
+	s := tc.Class
+
+	if len(tc.Composites) > 0 {
+		parts := make([]string, len(tc.Composites))
+		for i, c := range tc.Composites {
+			parts[i] = c.String()
+		}
+		s = tc.Class + "(" + strings.Join(parts, ",") + ")"
+	} else if len(tc.Options) > 0 {
+		keys := make([]string, 0, len(tc.Options))
+		for k := range tc.Options {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = k + "=" + tc.Options[k]
+		}
+		s = tc.Class + "(" + strings.Join(parts, ",") + ")"
+	}
+
+	if tc.Reversed {
+		s = reversedClassName + "(" + s + ")"
+	}
+
+	return s
+}
+
+// reverseBytes bitwise-complements every byte of b into a new slice. For
+// two encodings of the same length, such as two LongType values, a plain
+// byte-by-byte comparison of the complemented bytes gives the opposite of
+// their original order. Where one encoding is a prefix of the other (as
+// can happen with variable-length types such as UTF8Type), the shorter one
+// still sorts first after complementing. Applying reverseBytes a second
+// time recovers the original bytes.
+func reverseBytes(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i, c := range b {
+		r[i] = ^c
+	}
+	return r
+}
+
+// Equals reports whether tc and other describe the same comparator or
+// validator.
+func (tc TypeClass) Equals(other TypeClass) bool {
+	if tc.Class != other.Class || tc.Reversed != other.Reversed {
+		return false
+	}
+	if len(tc.Composites) != len(other.Composites) {
+		return false
+	}
+	for i := range tc.Composites {
+		if !tc.Composites[i].Equals(other.Composites[i]) {
+			return false
+		}
+	}
+	if len(tc.Options) != len(other.Options) {
+		return false
+	}
+	for k, v := range tc.Options {
+		if other.Options[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package gossie
+
+import "testing"
+
+func TestParseTypeClassRoundTrip(t *testing.T) {
+	cases := []string{
+		"org.apache.cassandra.db.marshal.UTF8Type",
+		"org.apache.cassandra.db.marshal.ReversedType(org.apache.cassandra.db.marshal.LongType)",
+		"org.apache.cassandra.db.marshal.CompositeType(org.apache.cassandra.db.marshal.UTF8Type,org.apache.cassandra.db.marshal.ReversedType(org.apache.cassandra.db.marshal.LongType))",
+	}
+	for _, raw := range cases {
+		tc := parseTypeClass(raw)
+		if got := tc.String(); got != raw {
+			t.Errorf("parseTypeClass(%q).String() = %q, want %q", raw, got, raw)
+		}
+	}
+}
+
+func TestReverseBytesIsInvolution(t *testing.T) {
+	b := []byte{0x00, 0x01, 0xff, 0x7f}
+	if got := reverseBytes(reverseBytes(b)); string(got) != string(b) {
+		t.Errorf("reverseBytes(reverseBytes(b)) = %v, want %v", got, b)
+	}
+}
+
+// TestReversedOrdering checks the property reverseBytes exists for: plain
+// byte comparison on the complemented encoding of an ascending-ordered pair
+// inverts their relative order, the same effect Cassandra's ReversedType
+// has on comparisons of the underlying type.
+func TestReversedOrdering(t *testing.T) {
+	lo := []byte{0x00, 0x00, 0x00, 0x01}
+	hi := []byte{0x00, 0x00, 0x00, 0x02}
+
+	if !bytesLess(lo, hi) {
+		t.Fatal("test fixture invariant broken: lo should sort before hi")
+	}
+	if !bytesLess(reverseBytes(hi), reverseBytes(lo)) {
+		t.Fatal("reverseBytes did not invert the ordering of lo and hi")
+	}
+}
+
+func bytesLess(a, b []byte) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
@@ -0,0 +1,153 @@
+package gossie
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validate cross-checks a Mapping against the live schema of the column
+// family it targets, returning every mismatch it finds instead of stopping
+// at the first one so a single run can report a full drift report.
+//
+// It only understands *sparseMapping and *compactMapping, the two concrete
+// Mapping implementations built by NewMapping.
+func Validate(mapping Mapping, cf *ColumnFamily) []error {
+	var errs []error
+
+	var si *structInspection
+	var key string
+	var components []string
+	var value string
+	compact := false
+
+	switch m := mapping.(type) {
+	case *compactMapping:
+		si, key, components, value, compact = m.si, m.key, m.components, m.value, true
+	case *sparseMapping:
+		si, key, components = m.si, m.key, m.components
+	default:
+		return []error{errors.New("Validate: unsupported Mapping implementation")}
+	}
+
+	// (a) key validator matches the cassandraType of the key field
+	if keyField, found := si.goFields[key]; found {
+		if !cf.KeyValidator.Equals(keyField.cassandraType) {
+			errs = append(errs, fmt.Errorf("Key validator mismatch for field %s: schema has %s, struct tag has %s", key, cf.KeyValidator, keyField.cassandraType))
+		}
+	}
+
+	// (b) comparator matches the composite of the cols fields
+	if len(components) > 0 {
+		comparator := cf.DefaultComparator
+		if !compact {
+			// sparse mappings append a trailing UTF8Type component holding
+			// the column name, see sparseMapping.Map
+			if len(comparator.Composites) == 0 || comparator.Composites[len(comparator.Composites)-1].Class != utf8ClassName {
+				errs = append(errs, fmt.Errorf("Comparator for %s is missing the trailing UTF8Type column name component", mapping.Cf()))
+			} else {
+				comparator = TypeClass{Composites: comparator.Composites[:len(comparator.Composites)-1]}
+			}
+		}
+		if len(comparator.Composites) != len(components) {
+			errs = append(errs, fmt.Errorf("Comparator for %s has %d composite components, mapping declares %d", mapping.Cf(), len(comparator.Composites), len(components)))
+		} else {
+			for i, c := range components {
+				f, found := si.goFields[c]
+				if !found {
+					continue
+				}
+				if !comparator.Composites[i].Equals(f.cassandraType) {
+					errs = append(errs, fmt.Errorf("Comparator component %d mismatch for field %s: schema has %s, struct tag has %s", i, c, comparator.Composites[i], f.cassandraType))
+				}
+			}
+		}
+	} else if !compact {
+		if !cf.DefaultComparator.Equals(TypeClass{Class: utf8ClassName}) {
+			errs = append(errs, fmt.Errorf("Comparator for %s should be UTF8Type for a sparse mapping with no composite components, schema has %s", mapping.Cf(), cf.DefaultComparator))
+		}
+	}
+
+	// (c) each named-column validator matches the corresponding struct
+	// field's cassandraType
+	for _, f := range si.orderedFields {
+		if f.name == key || (compact && f.name == value) {
+			continue
+		}
+		if inComponents(f.name, components) {
+			continue
+		}
+		if isDynamicField(f) {
+			// map/slice/discriminator fields are expanded into several
+			// dynamically named columns, so there is no single named
+			// column to cross-check against cf.NamedColumns
+			continue
+		}
+		// cf.NamedColumns is keyed by the actual Cassandra column name
+		// (colDef.Name over Thrift), which is f.cassandraName whenever the
+		// field carries a 'name' tag, not the Go field name
+		validator, found := cf.NamedColumns[f.cassandraName]
+		if !found {
+			errs = append(errs, fmt.Errorf("Column %s declared in struct tags is missing from the schema for %s", f.cassandraName, mapping.Cf()))
+			continue
+		}
+		if !validator.Equals(f.cassandraType) {
+			errs = append(errs, fmt.Errorf("Validator mismatch for column %s: schema has %s, struct tag has %s", f.cassandraName, validator, f.cassandraType))
+		}
+	}
+
+	// (d) for compact mappings the default validator matches the value field
+	if compact {
+		if valueField, found := si.goFields[value]; found {
+			if !cf.DefaultValidator.Equals(valueField.cassandraType) {
+				errs = append(errs, fmt.Errorf("Default validator mismatch for compact value field %s: schema has %s, struct tag has %s", value, cf.DefaultValidator, valueField.cassandraType))
+			}
+		}
+	}
+
+	return errs
+}
+
+func inComponents(name string, components []string) bool {
+	for _, c := range components {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateMappings builds a Mapping for each passed, gossie-tagged struct
+// value and validates it against the connection's live keyspace schema,
+// returning a single combined error if any mismatch is found. Running this
+// once at bootstrap turns a schema drift into a fast startup failure
+// instead of a mysterious unmarshal error surfacing deep into a batch.
+func (c *Connection) ValidateMappings(sources ...interface{}) error {
+	schema, err := c.Schema()
+	if err != nil {
+		return err
+	}
+
+	var allErrs []error
+	for _, source := range sources {
+		mapping, err := NewMapping(source)
+		if err != nil {
+			return err
+		}
+		cf, found := schema.ColumnFamilies[mapping.Cf()]
+		if !found {
+			allErrs = append(allErrs, fmt.Errorf("Column family %s not found in keyspace schema", mapping.Cf()))
+			continue
+		}
+		allErrs = append(allErrs, Validate(mapping, cf)...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("%d schema mismatch(es) found:", len(allErrs))
+	for _, e := range allErrs {
+		msg += "\n  " + e.Error()
+	}
+	return errors.New(msg)
+}
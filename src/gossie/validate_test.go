@@ -0,0 +1,108 @@
+package gossie
+
+import "testing"
+
+type validateSparseEntity struct {
+	_      struct{} `cf:"validate_sparse" key:"Id" cols:"Bucket"`
+	Id     string
+	Bucket int64
+	Name   string `name:"display_name"`
+}
+
+func sparseEntityCf() *ColumnFamily {
+	return &ColumnFamily{
+		KeyValidator:      UTF8Type,
+		DefaultComparator: TypeClass{Class: compositeClassName, Composites: []TypeClass{LongType, UTF8Type}},
+		NamedColumns:      map[string]TypeClass{"display_name": UTF8Type},
+	}
+}
+
+func TestValidateSparseClean(t *testing.T) {
+	mapping, err := NewMapping(&validateSparseEntity{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := Validate(mapping, sparseEntityCf()); len(errs) != 0 {
+		t.Fatalf("Validate() = %v, want no errors", errs)
+	}
+}
+
+// TestValidateKeyMismatch exercises check (a): the key validator must match
+// the key field's cassandraType.
+func TestValidateKeyMismatch(t *testing.T) {
+	mapping, err := NewMapping(&validateSparseEntity{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf := sparseEntityCf()
+	cf.KeyValidator = LongType
+
+	errs := Validate(mapping, cf)
+	if len(errs) != 1 {
+		t.Fatalf("Validate() = %v, want exactly 1 error", errs)
+	}
+}
+
+// TestValidateComparatorMismatch exercises check (b): the comparator must
+// be a composite of the 'cols' fields plus the trailing UTF8Type column
+// name component.
+func TestValidateComparatorMismatch(t *testing.T) {
+	mapping, err := NewMapping(&validateSparseEntity{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf := sparseEntityCf()
+	cf.DefaultComparator = TypeClass{Class: compositeClassName, Composites: []TypeClass{UTF8Type, UTF8Type}}
+
+	errs := Validate(mapping, cf)
+	if len(errs) == 0 {
+		t.Fatal("Validate() returned no errors, want a comparator component mismatch")
+	}
+}
+
+// TestValidateRenamedColumn exercises the fix for looking up NamedColumns
+// by a field's cassandraName rather than its Go field name.
+func TestValidateRenamedColumn(t *testing.T) {
+	mapping, err := NewMapping(&validateSparseEntity{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf := sparseEntityCf()
+	delete(cf.NamedColumns, "display_name")
+	cf.NamedColumns["Name"] = UTF8Type
+
+	errs := Validate(mapping, cf)
+	if len(errs) != 1 {
+		t.Fatalf("Validate() = %v, want exactly 1 missing-column error for the renamed field", errs)
+	}
+}
+
+// TestValidateCompactDefaultValidator exercises check (d): the default
+// validator must match the compact mapping's value field.
+func TestValidateCompactDefaultValidator(t *testing.T) {
+	type validateCompactEntity struct {
+		_     struct{} `cf:"validate_compact" key:"Id" value:"Count" mapping:"compact"`
+		Id    string
+		Count int64
+	}
+
+	mapping, err := NewMapping(&validateCompactEntity{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cf := &ColumnFamily{
+		KeyValidator:      UTF8Type,
+		DefaultComparator: UTF8Type,
+		DefaultValidator:  UTF8Type,
+		NamedColumns:      map[string]TypeClass{},
+	}
+	if errs := Validate(mapping, cf); len(errs) != 1 {
+		t.Fatalf("Validate() = %v, want exactly 1 default validator mismatch", errs)
+	}
+
+	cf.DefaultValidator = LongType
+	if errs := Validate(mapping, cf); len(errs) != 0 {
+		t.Fatalf("Validate() = %v, want no errors once the default validator matches", errs)
+	}
+}